@@ -0,0 +1,261 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/ariadata/tongen/internal/hdkey"
+)
+
+// jobRequest is the JSON body accepted by POST /jobs. Its fields mirror the CLI flags in
+// parseFlags so a job submitted over the API is configured the same way as one started from
+// the command line.
+type jobRequest struct {
+	Versions      string   `json:"versions"`
+	SubwalletID   *int64   `json:"subwallet_id"`
+	Prefixes      []string `json:"prefixes"`
+	Contains      []string `json:"contains"`
+	Suffixes      []string `json:"suffixes"`
+	Regexes       []string `json:"regexes"`
+	PatternsFile  string   `json:"patterns_file"`
+	OutputDir     string   `json:"output_dir"`
+	Continue      bool     `json:"continue"`
+	CaseSensitive bool     `json:"case_sensitive"`
+	Bounce        bool     `json:"bounce"`
+	Threads       int      `json:"threads"`
+	Testnet       bool     `json:"testnet"`
+	Output        string   `json:"output"`
+	Fast          bool     `json:"fast"`
+	PubkeySearch  string   `json:"pubkey_search"`
+}
+
+// jobSummary is the JSON representation of a Job returned by the API.
+type jobSummary struct {
+	ID        string     `json:"id"`
+	Status    JobStatus  `json:"status"`
+	Attempts  uint64     `json:"attempts"`
+	Rate      float64    `json:"attempts_per_sec"`
+	ETA       string     `json:"eta,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	StartedAt time.Time  `json:"started_at"`
+	Matches   []JobMatch `json:"matches"`
+}
+
+func summarize(j *Job) jobSummary {
+	s := jobSummary{
+		ID:        j.ID,
+		Status:    j.Status(),
+		Attempts:  j.Attempts(),
+		Rate:      j.RecentRate(),
+		CreatedAt: j.CreatedAt,
+		StartedAt: j.StartedAt,
+		Matches:   j.Matches(),
+	}
+	if eta := j.ETA(); eta > 0 {
+		s.ETA = eta.String()
+	}
+	return s
+}
+
+// serveAPI starts the HTTP control API on addr and blocks until the server exits (it only
+// returns on a listen/serve error, since the daemon is meant to run it for its whole lifetime).
+// When token is non-empty, every request must carry "Authorization: Bearer <token>". Since a
+// job's matches (GET /jobs/{id}) include whatever private key/seed/child index won, running
+// without a token on anything but loopback would hand that secret to anyone who can reach addr.
+func serveAPI(addr, token string, scheduler *Scheduler) error {
+	if token == "" {
+		if !isLoopbackAddr(addr) {
+			return fmt.Errorf("refusing to serve the control API on %q with no --api-token: found wallet secrets would be readable by anyone who can reach this address", addr)
+		}
+		log.Printf("WARNING: serving control API on %s with no --api-token -- any process on this machine can read found wallet secrets from it", addr)
+	}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /jobs", requireAuth(token, handleCreateJob(scheduler)))
+	mux.HandleFunc("GET /jobs", requireAuth(token, handleListJobs(scheduler)))
+	mux.HandleFunc("GET /jobs/{id}", requireAuth(token, handleGetJob(scheduler)))
+	mux.HandleFunc("DELETE /jobs/{id}", requireAuth(token, handleDeleteJob(scheduler)))
+	mux.HandleFunc("GET /metrics", requireAuth(token, handleMetrics(scheduler)))
+
+	return http.ListenAndServe(addr, mux)
+}
+
+// isLoopbackAddr reports whether addr (as passed to http.ListenAndServe, e.g. ":8080",
+// "127.0.0.1:8080" or "localhost:8080") only accepts connections from this machine. A host-less
+// addr binds every interface, so it's treated as non-loopback.
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func requireAuth(token string, next http.HandlerFunc) http.HandlerFunc {
+	if token == "" {
+		return next
+	}
+	want := []byte("Bearer " + token)
+	return func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		if len(got) != len(want) || subtle.ConstantTimeCompare(got, want) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func handleCreateJob(scheduler *Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req jobRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		subwalletID := int64(-1)
+		if req.SubwalletID != nil {
+			subwalletID = *req.SubwalletID
+		}
+
+		if req.Versions == "" {
+			req.Versions = "v5r1"
+		}
+		specs, err := parseVersions(req.Versions, req.Testnet, subwalletID)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid versions: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		patterns, err := NewPatternSet(req.Prefixes, req.Contains, req.Suffixes, req.Regexes, req.PatternsFile, req.CaseSensitive)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid pattern configuration: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		if req.PubkeySearch != "" {
+			if req.Fast {
+				http.Error(w, "pubkey_search and fast are mutually exclusive: fast generates its own private keys, defeating the point of a worker that never sees one", http.StatusBadRequest)
+				return
+			}
+			if _, err := hdkey.ParsePublicKey(req.PubkeySearch); err != nil {
+				http.Error(w, fmt.Sprintf("invalid pubkey_search: %v", err), http.StatusBadRequest)
+				return
+			}
+		}
+
+		config := Config{
+			Versions:      specs,
+			SubwalletID:   subwalletID,
+			Patterns:      patterns,
+			OutputDir:     req.OutputDir,
+			Continue:      req.Continue,
+			CaseSensitive: req.CaseSensitive,
+			Bounce:        req.Bounce,
+			Threads:       req.Threads,
+			Testnet:       req.Testnet,
+			Output:        req.Output,
+			Fast:          req.Fast,
+			PubkeySearch:  req.PubkeySearch,
+		}
+
+		job := scheduler.Submit(config)
+		log.Printf("API: submitted job %s", job.ID)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(summarize(job))
+	}
+}
+
+func handleListJobs(scheduler *Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jobs := scheduler.List()
+		summaries := make([]jobSummary, 0, len(jobs))
+		for _, j := range jobs {
+			summaries = append(summaries, summarize(j))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(summaries)
+	}
+}
+
+func handleGetJob(scheduler *Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		job, ok := scheduler.Get(r.PathValue("id"))
+		if !ok {
+			http.Error(w, "job not found", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(summarize(job))
+	}
+}
+
+func handleDeleteJob(scheduler *Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !scheduler.Cancel(r.PathValue("id")) {
+			http.Error(w, "job not found", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// handleMetrics renders Prometheus text-exposition-format metrics across every job the
+// scheduler knows about.
+func handleMetrics(scheduler *Scheduler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		jobs := scheduler.List()
+
+		var totalAttempts uint64
+		var totalRate float64
+		var totalMatches int
+		for _, j := range jobs {
+			totalAttempts += j.Attempts()
+			totalRate += j.RecentRate()
+			totalMatches += len(j.Matches())
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		fmt.Fprintf(w, "# HELP tongen_jobs_total Number of jobs known to the scheduler.\n")
+		fmt.Fprintf(w, "# TYPE tongen_jobs_total gauge\n")
+		fmt.Fprintf(w, "tongen_jobs_total %d\n", len(jobs))
+
+		fmt.Fprintf(w, "# HELP tongen_attempts_total Addresses generated across all jobs.\n")
+		fmt.Fprintf(w, "# TYPE tongen_attempts_total counter\n")
+		fmt.Fprintf(w, "tongen_attempts_total %d\n", totalAttempts)
+
+		fmt.Fprintf(w, "# HELP tongen_attempts_per_second Current generation rate across all jobs.\n")
+		fmt.Fprintf(w, "# TYPE tongen_attempts_per_second gauge\n")
+		fmt.Fprintf(w, "tongen_attempts_per_second %f\n", totalRate)
+
+		fmt.Fprintf(w, "# HELP tongen_matches_total Pattern matches found across all jobs.\n")
+		fmt.Fprintf(w, "# TYPE tongen_matches_total counter\n")
+		fmt.Fprintf(w, "tongen_matches_total %d\n", totalMatches)
+
+		fmt.Fprintf(w, "# HELP tongen_goroutines Number of goroutines running in the process.\n")
+		fmt.Fprintf(w, "# TYPE tongen_goroutines gauge\n")
+		fmt.Fprintf(w, "tongen_goroutines %d\n", runtime.NumGoroutine())
+
+		for _, j := range jobs {
+			fmt.Fprintf(w, "tongen_job_attempts_total{job=%q} %d\n", j.ID, j.Attempts())
+			fmt.Fprintf(w, "tongen_job_attempts_per_second{job=%q} %f\n", j.ID, j.RecentRate())
+			fmt.Fprintf(w, "tongen_job_matches_total{job=%q} %d\n", j.ID, len(j.Matches()))
+		}
+	}
+}
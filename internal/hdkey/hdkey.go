@@ -0,0 +1,257 @@
+// Package hdkey implements non-hardened BIP32-style hierarchical deterministic derivation for
+// Ed25519 keys, in the spirit of SLIP-0010 and the Khovratovich-Law ("BIP32-Ed25519") scheme.
+//
+// Standard SLIP-0010 Ed25519 derivation is hardened-only, since Ed25519 private scalars are
+// normally produced by clamping a SHA-512 hash of a seed, which breaks the linearity public
+// derivation needs. Khovratovich-Law works around this by keeping the private key as a raw,
+// unclamped scalar instead, so a child's public point can be derived from the parent's public
+// point and chain code alone (CKDpub), with no secret material involved. That is exactly what
+// a splitkey worker (see splitkey.go) needs: it enumerates child indices and computes addresses
+// for an ExtendedPublicKey it was handed, never touching an ExtendedPrivateKey.
+//
+// This package implements that public derivation, plus the matching private-key side so a
+// client can recombine a winning index into the full key. It does not aim for byte-level
+// compatibility with any single external spec (e.g. Cardano's CIP-3) -- the derivation only
+// needs to be consistent between tongen's own splitkey init/combine and its worker.
+//
+// A recovered ExtendedPrivateKey cannot be used as a crypto/ed25519 seed: ed25519.PrivateKey
+// is always seed(32)||pubkey(32), and crypto/ed25519's Sign re-hashes and clamps that seed
+// before using it as a scalar, which would sign with a different effective scalar than the one
+// ExtendedPublicKey.Child used to compute the address. Use ExtendedPrivateKey.Sign instead,
+// which signs with the exact raw scalar the address was derived from.
+package hdkey
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/oasisprotocol/curve25519-voi/curve"
+	"github.com/oasisprotocol/curve25519-voi/curve/scalar"
+)
+
+// seedHMACKey is the domain-separation key BIP32-Ed25519 derivations use to turn a seed into a
+// master scalar and chain code.
+const seedHMACKey = "ed25519 seed"
+
+// hardenedOffset marks child indices reserved for hardened derivation. This package only
+// implements the non-hardened branch, since the whole point of splitkey is a worker deriving
+// children from a public key alone; indices at or above it are rejected.
+const hardenedOffset = uint32(1) << 31
+
+// ExtendedPrivateKey is a secret Ed25519 scalar plus a chain code. Unlike a crypto/ed25519 seed,
+// the scalar is never hashed or clamped, which is what makes CKDpub possible -- so it cannot be
+// round-tripped through crypto/ed25519.NewKeyFromSeed or turned into a BIP39 mnemonic.
+type ExtendedPrivateKey struct {
+	scalar    scalar.Scalar
+	chainCode [32]byte
+}
+
+// ExtendedPublicKey is the public half of an ExtendedPrivateKey: a curve point plus the same
+// chain code. It is sufficient on its own to derive every non-hardened descendant's address,
+// which is the one property splitkey depends on.
+type ExtendedPublicKey struct {
+	point     curve.EdwardsPoint
+	chainCode [32]byte
+}
+
+// NewMasterKey derives the root ExtendedPrivateKey for seed.
+func NewMasterKey(seed []byte) (*ExtendedPrivateKey, error) {
+	mac := hmac.New(sha512.New, []byte(seedHMACKey))
+	mac.Write(seed)
+	i := mac.Sum(nil)
+
+	s, err := scalar.New().SetBytesModOrder(i[:32])
+	if err != nil {
+		return nil, fmt.Errorf("hdkey: deriving master scalar: %w", err)
+	}
+
+	k := &ExtendedPrivateKey{scalar: *s}
+	copy(k.chainCode[:], i[32:])
+	return k, nil
+}
+
+// Public returns the public half of k.
+func (k *ExtendedPrivateKey) Public() *ExtendedPublicKey {
+	point := curve.NewEdwardsPoint().MulBasepoint(curve.ED25519_BASEPOINT_TABLE, &k.scalar)
+	return &ExtendedPublicKey{point: *point, chainCode: k.chainCode}
+}
+
+// Child derives the non-hardened child private key at index (CKDpriv). It returns the same
+// key a worker holding only k.Public() would find by calling ExtendedPublicKey.Child(index).
+func (k *ExtendedPrivateKey) Child(index uint32) (*ExtendedPrivateKey, error) {
+	tweak, chainCode, err := childTweak(k.Public(), index)
+	if err != nil {
+		return nil, err
+	}
+
+	childScalar := scalar.New().Add(&k.scalar, tweak)
+	return &ExtendedPrivateKey{scalar: *childScalar, chainCode: chainCode}, nil
+}
+
+// Child derives the non-hardened child public key at index (CKDpub) -- the operation a
+// splitkey worker repeats for every index it enumerates, without ever holding a private key.
+func (pk *ExtendedPublicKey) Child(index uint32) (*ExtendedPublicKey, error) {
+	tweak, chainCode, err := childTweak(pk, index)
+	if err != nil {
+		return nil, err
+	}
+
+	tweakPoint := curve.NewEdwardsPoint().MulBasepoint(curve.ED25519_BASEPOINT_TABLE, tweak)
+	childPoint := curve.NewEdwardsPoint().Add(&pk.point, tweakPoint)
+	return &ExtendedPublicKey{point: *childPoint, chainCode: chainCode}, nil
+}
+
+// childTweak computes the scalar tweak and next chain code shared by CKDpriv and CKDpub. Both
+// derive solely from the parent's public point and chain code, which is what lets a worker that
+// only has an ExtendedPublicKey walk the same child sequence as the key's owner.
+func childTweak(parentPub *ExtendedPublicKey, index uint32) (*scalar.Scalar, [32]byte, error) {
+	if index >= hardenedOffset {
+		return nil, [32]byte{}, errors.New("hdkey: hardened child indices are not supported (would require a secret)")
+	}
+
+	mac := hmac.New(sha512.New, parentPub.chainCode[:])
+	mac.Write(parentPub.Bytes())
+	var idx [4]byte
+	binary.LittleEndian.PutUint32(idx[:], index)
+	mac.Write(idx[:])
+	i := mac.Sum(nil)
+
+	tweak, err := scalar.New().SetBytesModOrder(i[:32])
+	if err != nil {
+		return nil, [32]byte{}, fmt.Errorf("hdkey: deriving child tweak: %w", err)
+	}
+
+	var chainCode [32]byte
+	copy(chainCode[:], i[32:])
+	return tweak, chainCode, nil
+}
+
+// Bytes returns pk's compressed 32-byte Edwards point, which is the literal encoding
+// crypto/ed25519.PublicKey uses -- in Ed25519 the public key IS the compressed point.
+func (pk *ExtendedPublicKey) Bytes() []byte {
+	b, _ := curve.NewCompressedEdwardsY().SetEdwardsPoint(&pk.point).MarshalBinary()
+	return b
+}
+
+// PublicKey returns pk's point as a standard crypto/ed25519.PublicKey, ready to pass straight
+// into tonutils-go's wallet.AddressFromPubKey.
+func (pk *ExtendedPublicKey) PublicKey() ed25519.PublicKey {
+	return ed25519.PublicKey(pk.Bytes())
+}
+
+// Marshal encodes pk as hex: its 32-byte compressed point followed by its 32-byte chain code.
+// This is the string a splitkey client sends a worker, and that ParsePublicKey parses back.
+func (pk *ExtendedPublicKey) Marshal() string {
+	b := make([]byte, 0, 64)
+	b = append(b, pk.Bytes()...)
+	b = append(b, pk.chainCode[:]...)
+	return hex.EncodeToString(b)
+}
+
+// ParsePublicKey parses the hex form produced by ExtendedPublicKey.Marshal.
+func ParsePublicKey(s string) (*ExtendedPublicKey, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("hdkey: invalid hex: %w", err)
+	}
+	if len(b) != 64 {
+		return nil, fmt.Errorf("hdkey: expected 64 bytes (32-byte point + 32-byte chain code), got %d", len(b))
+	}
+
+	compressed, err := curve.NewCompressedEdwardsYFromBytes(b[:32])
+	if err != nil {
+		return nil, fmt.Errorf("hdkey: invalid point: %w", err)
+	}
+	point, err := curve.NewEdwardsPoint().SetCompressedY(compressed)
+	if err != nil {
+		return nil, fmt.Errorf("hdkey: point is not on curve: %w", err)
+	}
+
+	pk := &ExtendedPublicKey{point: *point}
+	copy(pk.chainCode[:], b[32:])
+	return pk, nil
+}
+
+// Sign produces a valid EdDSA signature of message under k, verifiable with a standard
+// ed25519.Verify(k.Public().PublicKey(), message, sig). It cannot use crypto/ed25519.Sign,
+// which requires a 32-byte seed and derives both the scalar and the deterministic nonce prefix
+// from it by hashing -- k's scalar is raw (see the package doc), and has no such prefix. Instead
+// the nonce is drawn fresh from crypto/rand for every signature: EdDSA's verification equation
+// only depends on R = r*B being consistent with S = r + H(R||A||M)*s, not on how r was chosen,
+// so a securely random r is a safe substitute for the usual hash-derived one.
+func (k *ExtendedPrivateKey) Sign(message []byte) ([]byte, error) {
+	var nonceSeed [64]byte
+	if _, err := rand.Read(nonceSeed[:]); err != nil {
+		return nil, fmt.Errorf("hdkey: generating nonce: %w", err)
+	}
+	r, err := scalar.New().SetBytesModOrderWide(nonceSeed[:])
+	if err != nil {
+		return nil, fmt.Errorf("hdkey: reducing nonce: %w", err)
+	}
+
+	R := curve.NewEdwardsPoint().MulBasepoint(curve.ED25519_BASEPOINT_TABLE, r)
+	Rbytes, err := curve.NewCompressedEdwardsY().SetEdwardsPoint(R).MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("hdkey: encoding nonce point: %w", err)
+	}
+	Abytes := k.Public().Bytes()
+
+	h := sha512.New()
+	h.Write(Rbytes)
+	h.Write(Abytes)
+	h.Write(message)
+	challenge, err := scalar.New().SetBytesModOrderWide(h.Sum(nil))
+	if err != nil {
+		return nil, fmt.Errorf("hdkey: reducing challenge: %w", err)
+	}
+
+	s := scalar.New().Add(r, scalar.New().Mul(challenge, &k.scalar))
+	var sBytes [32]byte
+	if err := s.ToBytes(sBytes[:]); err != nil {
+		return nil, fmt.Errorf("hdkey: encoding signature scalar: %w", err)
+	}
+
+	sig := make([]byte, 0, 64)
+	sig = append(sig, Rbytes...)
+	sig = append(sig, sBytes[:]...)
+	return sig, nil
+}
+
+// Marshal encodes k as hex: its 32-byte scalar followed by its 32-byte chain code. Meant for
+// local storage only (e.g. "tongen splitkey init"'s key file) -- unlike ExtendedPublicKey's
+// Marshal, this one must never be sent to a worker.
+func (k *ExtendedPrivateKey) Marshal() string {
+	var sb [32]byte
+	_ = k.scalar.ToBytes(sb[:])
+
+	b := make([]byte, 0, 64)
+	b = append(b, sb[:]...)
+	b = append(b, k.chainCode[:]...)
+	return hex.EncodeToString(b)
+}
+
+// ParsePrivateKey parses the hex form produced by ExtendedPrivateKey.Marshal.
+func ParsePrivateKey(s string) (*ExtendedPrivateKey, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("hdkey: invalid hex: %w", err)
+	}
+	if len(b) != 64 {
+		return nil, fmt.Errorf("hdkey: expected 64 bytes (32-byte scalar + 32-byte chain code), got %d", len(b))
+	}
+
+	s2, err := scalar.New().SetCanonicalBytes(b[:32])
+	if err != nil {
+		return nil, fmt.Errorf("hdkey: invalid scalar: %w", err)
+	}
+
+	k := &ExtendedPrivateKey{scalar: *s2}
+	copy(k.chainCode[:], b[32:])
+	return k, nil
+}
@@ -0,0 +1,114 @@
+package hdkey
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"testing"
+)
+
+func TestChildPubPrivAgree(t *testing.T) {
+	master, err := NewMasterKey([]byte("test seed, not a real one"))
+	if err != nil {
+		t.Fatalf("NewMasterKey: %v", err)
+	}
+
+	for _, index := range []uint32{0, 1, 41, 1000} {
+		privChild, err := master.Child(index)
+		if err != nil {
+			t.Fatalf("Child(%d) on private key: %v", index, err)
+		}
+		pubChild, err := master.Public().Child(index)
+		if err != nil {
+			t.Fatalf("Child(%d) on public key: %v", index, err)
+		}
+
+		if !bytes.Equal(privChild.Public().Bytes(), pubChild.Bytes()) {
+			t.Errorf("index %d: CKDpriv's public half and CKDpub disagree", index)
+		}
+	}
+}
+
+func TestChildRejectsHardenedIndex(t *testing.T) {
+	master, err := NewMasterKey([]byte("seed"))
+	if err != nil {
+		t.Fatalf("NewMasterKey: %v", err)
+	}
+
+	if _, err := master.Child(hardenedOffset); err == nil {
+		t.Error("Child(hardenedOffset) should fail: hardened derivation needs a secret")
+	}
+	if _, err := master.Public().Child(hardenedOffset); err == nil {
+		t.Error("Public().Child(hardenedOffset) should fail: hardened derivation needs a secret")
+	}
+}
+
+func TestSignVerifiesUnderStandardEd25519(t *testing.T) {
+	master, err := NewMasterKey([]byte("another seed"))
+	if err != nil {
+		t.Fatalf("NewMasterKey: %v", err)
+	}
+	child, err := master.Child(7)
+	if err != nil {
+		t.Fatalf("Child: %v", err)
+	}
+
+	message := []byte("sign me")
+	sig, err := child.Sign(message)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	if !ed25519.Verify(child.Public().PublicKey(), message, sig) {
+		t.Error("ed25519.Verify rejected a signature produced by Sign")
+	}
+	if ed25519.Verify(child.Public().PublicKey(), []byte("different message"), sig) {
+		t.Error("ed25519.Verify accepted a signature over the wrong message")
+	}
+}
+
+func TestSignIsRandomizedPerCall(t *testing.T) {
+	master, err := NewMasterKey([]byte("yet another seed"))
+	if err != nil {
+		t.Fatalf("NewMasterKey: %v", err)
+	}
+
+	message := []byte("sign me twice")
+	sig1, err := master.Sign(message)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	sig2, err := master.Sign(message)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	if bytes.Equal(sig1, sig2) {
+		t.Error("two signatures over the same message used the same nonce (not randomized)")
+	}
+	if !ed25519.Verify(master.Public().PublicKey(), message, sig1) || !ed25519.Verify(master.Public().PublicKey(), message, sig2) {
+		t.Error("both randomized signatures must still verify")
+	}
+}
+
+func TestMarshalParseRoundTrip(t *testing.T) {
+	master, err := NewMasterKey([]byte("round trip seed"))
+	if err != nil {
+		t.Fatalf("NewMasterKey: %v", err)
+	}
+
+	privRoundTrip, err := ParsePrivateKey(master.Marshal())
+	if err != nil {
+		t.Fatalf("ParsePrivateKey: %v", err)
+	}
+	if !bytes.Equal(privRoundTrip.Public().Bytes(), master.Public().Bytes()) {
+		t.Error("ParsePrivateKey(Marshal()) produced a different public key")
+	}
+
+	pubRoundTrip, err := ParsePublicKey(master.Public().Marshal())
+	if err != nil {
+		t.Fatalf("ParsePublicKey: %v", err)
+	}
+	if !bytes.Equal(pubRoundTrip.Bytes(), master.Public().Bytes()) {
+		t.Error("ParsePublicKey(Marshal()) produced a different point")
+	}
+}
@@ -1,30 +1,50 @@
 package main
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"runtime"
 	"strings"
-	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/ariadata/tongen/internal/hdkey"
 	"github.com/sevlyar/go-daemon"
 	"github.com/xssnick/tonutils-go/ton/wallet"
 )
 
 // Input parameters
 type Config struct {
-	Version       int
-	Suffix        string
+	Versions      []VersionSpec
+	SubwalletID   int64 // -1 means "use each version's own default subwallet"
+	Patterns      *PatternSet
+	OutputDir     string
+	Continue      bool
 	CaseSensitive bool
 	Bounce        bool
 	Threads       int
 	Testnet       bool
 	Output        string
 	Daemon        bool
+	Fast          bool
+	APIAddr       string
+	APIToken      string
+	PubkeySearch  string // hex-encoded hdkey.ExtendedPublicKey from "tongen splitkey init"
+}
+
+// repeatableFlag collects every occurrence of a flag passed multiple times, e.g.
+// --prefix ABC --prefix XYZ.
+type repeatableFlag []string
+
+func (r *repeatableFlag) String() string { return strings.Join(*r, ",") }
+func (r *repeatableFlag) Set(v string) error {
+	*r = append(*r, v)
+	return nil
 }
 
 func main() {
@@ -34,6 +54,13 @@ func main() {
 		return
 	}
 
+	// "splitkey" is a standalone client-side command, not a search: it never touches the
+	// Scheduler/processWallets path below.
+	if len(os.Args) > 1 && os.Args[1] == "splitkey" {
+		runSplitKey(os.Args[2:])
+		return
+	}
+
 	// Check for daemon flag before parsing all flags
 	isDaemon := false
 	for _, arg := range os.Args {
@@ -87,38 +114,32 @@ func main() {
 	runWalletGenerator(config)
 }
 
-// runWalletGenerator contains the main wallet generation logic
+// runWalletGenerator runs config on a fresh Scheduler. With --api-addr set it starts the HTTP
+// control API and serves jobs submitted to it until killed, instead of running a single search:
+// this is what lets the daemon run many concurrent searches (see api.go). Otherwise it submits
+// config itself as a single job and blocks until it's done, preserving the CLI's original
+// single-search behavior.
 func runWalletGenerator(config Config) {
-	// Determine the number of threads (default: use all CPU cores if threads=0)
 	if config.Threads == 0 {
 		config.Threads = runtime.NumCPU()
 	}
-	log.Printf("Using %d threads\n", config.Threads)
 
-	// Channel to signal when a match is found
-	stopChan := make(chan struct{})
+	scheduler := NewScheduler()
 
-	// Use sync.Once to ensure stopChan is closed only once
-	var once sync.Once
-
-	// Start tracking the number of processed wallets
-	var counter uint64
-	var wg sync.WaitGroup
+	if config.APIAddr != "" {
+		log.Printf("Serving control API on %s\n", config.APIAddr)
+		if err := serveAPI(config.APIAddr, config.APIToken, scheduler); err != nil {
+			log.Fatalf("API server error: %v", err)
+		}
+		return
+	}
 
-	// Start logging progress every second
-	go logProgress(&counter, stopChan)
+	log.Printf("Using %d threads\n", config.Threads)
+	job := scheduler.Submit(config)
 
-	// Start wallet generation and processing
-	for i := 0; i < config.Threads; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			processWallets(config, &counter, stopChan, &once)
-		}()
-	}
+	go logProgress(job)
 
-	// Wait for all threads to finish
-	wg.Wait()
+	job.wg.Wait()
 }
 
 // parseFlags handles command-line input parameters
@@ -126,14 +147,29 @@ func parseFlags() Config {
 	// Create a new flag set to avoid conflicts
 	fs := flag.NewFlagSet("tongen", flag.ExitOnError)
 
-	version := fs.Int("version", 5, "Wallet version (4 or 5, default: 5)")
-	suffix := fs.String("suffix", "", "Desired contract address suffix (required)")
-	caseSensitive := fs.Bool("case-sensitive", false, "Enable case-sensitive suffix matching (default: false)")
+	versions := fs.String("versions", "v5r1", "Comma-separated wallet versions to search in one pass: v3r1, v3r2, v4r1, v4r2, v5r1beta, v5r1 (V5R1Final), highloadv2r2, highloadv3")
+	subwalletID := fs.Int64("subwallet-id", -1, "Subwallet/wallet ID used for address derivation (default: -1, meaning use each version's own default)")
+
+	var prefixes, contains, suffixes, regexes repeatableFlag
+	fs.Var(&prefixes, "prefix", "Desired address prefix (repeatable)")
+	fs.Var(&contains, "contains", "Substring the address must contain anywhere (repeatable)")
+	fs.Var(&suffixes, "suffix", "Desired contract address suffix (repeatable)")
+	fs.Var(&regexes, "regex", "Regex the address body must match, compiled once (repeatable)")
+	patternsFile := fs.String("patterns-file", "", "File with one pattern per line (\"prefix:ABC\", \"contains:xyz\", \"suffix:xyz\", \"regex:...\"; a bare line is treated as a suffix)")
+	outputDir := fs.String("output-dir", "", "Directory to append a per-pattern hit file to, named after the matched pattern")
+	continueSearch := fs.Bool("continue", false, "Keep searching after a match until every configured pattern has been satisfied at least once, instead of stopping on the first hit")
+
+	caseSensitive := fs.Bool("case-sensitive", false, "Enable case-sensitive pattern matching (default: false)")
 	bounce := fs.Bool("bounce", false, "Enable bounceable address (default: false)")
 	threads := fs.Int("threads", 0, "Number of parallel threads (default: 0, meaning use all CPU cores)")
 	testnet := fs.Bool("testnet", false, "Use testnet (default: false)")
 	output := fs.String("output", "", "Output file path to save results (use -o or --output)")
 	fs.StringVar(output, "o", "", "Output file path to save results (short form)")
+	fast := fs.Bool("fast", false, "Skip BIP39/PBKDF2 seed derivation: generate raw Ed25519 keys directly and only emit a mnemonic for the winning key (100-1000x faster)")
+	fs.BoolVar(fast, "raw-keys", false, "Alias for --fast")
+	apiAddr := fs.String("api-addr", "", "Address to serve the HTTP control API on (e.g. \":8080\"); if set, tongen starts the API instead of running a single search and exiting")
+	apiToken := fs.String("api-token", "", "Bearer token required on every API request (default: none, API is unauthenticated)")
+	pubkeySearch := fs.String("pubkey-search", "", "Hex-encoded extended public key from 'tongen splitkey init'; enumerate its child addresses by index instead of generating random keys, so this process never sees a private key (recover the winner with 'tongen splitkey combine')")
 
 	// Filter out daemon flags from arguments
 	var filteredArgs []string
@@ -147,109 +183,255 @@ func parseFlags() Config {
 	// Parse the filtered arguments
 	fs.Parse(filteredArgs[1:]) // Skip the program name
 
-	if *suffix == "" || (*version != 4 && *version != 5) {
-		fs.PrintDefaults()
-		os.Exit(1)
+	// In --api-addr mode no single search is configured up front: every job (and its own
+	// patterns/versions) is submitted over the HTTP API instead, so an empty pattern set here
+	// is valid rather than an error.
+	var patterns *PatternSet
+	var specs []VersionSpec
+	if *apiAddr == "" || len(prefixes)+len(contains)+len(suffixes)+len(regexes) > 0 || *patternsFile != "" {
+		var err error
+		patterns, err = NewPatternSet(prefixes, contains, suffixes, regexes, *patternsFile, *caseSensitive)
+		if err != nil {
+			log.Printf("Invalid pattern configuration: %v", err)
+			fs.PrintDefaults()
+			os.Exit(1)
+		}
+
+		specs, err = parseVersions(*versions, *testnet, *subwalletID)
+		if err != nil {
+			log.Printf("Invalid --versions: %v", err)
+			fs.PrintDefaults()
+			os.Exit(1)
+		}
+	}
+
+	if *pubkeySearch != "" {
+		if *fast {
+			log.Printf("--pubkey-search and --fast are mutually exclusive: --fast generates its own private keys, defeating the point of a worker that never sees one")
+			os.Exit(1)
+		}
+		if _, err := hdkey.ParsePublicKey(*pubkeySearch); err != nil {
+			log.Printf("Invalid --pubkey-search: %v", err)
+			fs.PrintDefaults()
+			os.Exit(1)
+		}
 	}
 
 	return Config{
-		Version:       *version,
-		Suffix:        *suffix,
+		Versions:      specs,
+		SubwalletID:   *subwalletID,
+		Patterns:      patterns,
+		OutputDir:     *outputDir,
+		Continue:      *continueSearch,
 		CaseSensitive: *caseSensitive,
 		Bounce:        *bounce,
 		Threads:       *threads,
 		Testnet:       *testnet,
 		Output:        *output,
 		Daemon:        false, // Daemon flag is handled in main function
+		Fast:          *fast,
+		APIAddr:       *apiAddr,
+		APIToken:      *apiToken,
+		PubkeySearch:  *pubkeySearch,
 	}
 }
 
-// processWallets generates wallets, checks if the address matches the suffix, and stops on a match
-func processWallets(config Config, counter *uint64, stopChan chan struct{}, once *sync.Once) {
+// processWallets generates wallets for job, checks every generated address against its
+// configured pattern set, and stops once the job's tracker says the search is done.
+func processWallets(job *Job) {
+	// Checked before Fast: --pubkey-search's whole point is that this process never generates
+	// a private key, so it must win even if --fast was also set somehow.
+	if job.Config.PubkeySearch != "" {
+		processWalletsPubkey(job)
+		return
+	}
+	if job.Config.Fast {
+		processWalletsFast(job)
+		return
+	}
+
+	config := job.Config
 	for {
 		select {
-		case <-stopChan:
+		case <-job.stopChan:
 			return
 		default:
-			// Generate the seed phrase
+			// Generate the seed phrase and derive the keypair once (the expensive step);
+			// every selected version's address is then computed from the same pubkey.
 			seed := wallet.NewSeed()
 
-			// Create a wallet based on the selected version (V4 or V5)
-			var addressStr string
-			var err error
+			priv, err := wallet.SeedToPrivateKey(seed, "", false)
+			if err != nil {
+				log.Printf("Failed to derive key from seed: %v", err)
+				continue
+			}
+
+			results, err := addressesForPubKey(priv.Public().(ed25519.PublicKey), config)
+			if err != nil {
+				log.Printf("Failed to create wallet: %v", err)
+				continue
+			}
 
-			if config.Version == 5 {
-				addressStr, err = generateV5Wallet(seed, config)
-			} else {
-				addressStr, err = generateV4Wallet(seed, config)
+			if handleResults(job, results, func(versionName, addressStr string) string {
+				printFoundWallet(seed, versionName, addressStr, config.Output)
+				return strings.Join(seed, " ")
+			}) {
+				return
 			}
 
+			atomic.AddUint64(&job.attempts, 1)
+		}
+	}
+}
+
+// processWalletsFast is the --fast code path: it skips BIP39 mnemonic generation and the
+// PBKDF2-HMAC-SHA512 seed derivation that dominates CPU time in the default path, generating
+// an Ed25519 keypair straight from crypto/rand and computing the wallet address from the
+// public key (StateInit hash) directly. A mnemonic is only derived once, for the winning key.
+func processWalletsFast(job *Job) {
+	config := job.Config
+	for {
+		select {
+		case <-job.stopChan:
+			return
+		default:
+			pub, priv, err := ed25519.GenerateKey(rand.Reader)
+			if err != nil {
+				log.Printf("Failed to generate key: %v", err)
+				continue
+			}
+
+			results, err := addressesForPubKey(pub, config)
 			if err != nil {
 				log.Printf("Failed to create wallet: %v", err)
 				continue
 			}
 
-			// Case-sensitive or case-insensitive suffix comparison
-			if config.CaseSensitive {
-				if strings.HasSuffix(addressStr, config.Suffix) {
-					printFoundWallet(seed, addressStr, config.Output)
-					once.Do(func() { close(stopChan) })
-					return
-				}
-			} else {
-				if strings.HasSuffix(strings.ToLower(addressStr), strings.ToLower(config.Suffix)) {
-					printFoundWallet(seed, addressStr, config.Output)
-					once.Do(func() { close(stopChan) })
-					return
-				}
+			if handleResults(job, results, func(versionName, addressStr string) string {
+				printFoundWalletFast(priv, versionName, addressStr, config.Output)
+				return hex.EncodeToString(priv.Seed())
+			}) {
+				return
 			}
 
-			// Increment the counter
-			atomic.AddUint64(counter, 1)
+			atomic.AddUint64(&job.attempts, 1)
 		}
 	}
 }
 
-// generateV5Wallet creates a V5 wallet and returns the corresponding address
-func generateV5Wallet(seed []string, config Config) (string, error) {
-	// Create a V5R1Final wallet using the seed
-	w, err := wallet.FromSeed(nil, seed, wallet.ConfigV5R1Final{
-		NetworkGlobalID: getNetworkID(config.Testnet),
-		Workchain:       0, // Base workchain
-	})
+// processWalletsPubkey is the --pubkey-search code path (the splitkey worker side): it derives
+// an ed25519.PublicKey for each attempt by walking child index i = 0, 1, 2, ... off of a
+// Config.PubkeySearch ExtendedPublicKey (hdkey.ExtendedPublicKey.Child), rather than generating
+// a keypair. The worker never has the corresponding private key, so a hit is recorded with only
+// the winning index; the client recovers the full wallet key with "tongen splitkey combine".
+func processWalletsPubkey(job *Job) {
+	config := job.Config
+
+	master, err := hdkey.ParsePublicKey(config.PubkeySearch)
 	if err != nil {
-		return "", err
+		log.Printf("Invalid --pubkey-search: %v", err)
+		return
 	}
 
-	// Get the wallet address
-	addr := w.WalletAddress()
-	addressStr := addr.Testnet(config.Testnet).Bounce(config.Bounce).String()
-	return addressStr, nil
+	for {
+		select {
+		case <-job.stopChan:
+			return
+		default:
+			index := uint32(atomic.AddUint64(&job.attempts, 1) - 1)
+
+			child, err := master.Child(index)
+			if err != nil {
+				// master.Child only fails once index reaches the non-hardened ceiling
+				// (2^31): every further index fails the same way, so stop the whole job
+				// instead of spinning forever re-hitting the same error.
+				log.Printf("Pubkey-search keyspace exhausted at index %d: %v", index, err)
+				job.once.Do(func() { close(job.stopChan) })
+				return
+			}
+
+			results, err := addressesForPubKey(child.PublicKey(), config)
+			if err != nil {
+				log.Printf("Failed to create wallet: %v", err)
+				continue
+			}
+
+			if handleResults(job, results, func(versionName, addressStr string) string {
+				printFoundWalletPubkey(index, versionName, addressStr, config.Output)
+				return fmt.Sprintf("%d", index)
+			}) {
+				return
+			}
+		}
+	}
 }
 
-// generateV4Wallet creates a V4 wallet and returns the corresponding address
-func generateV4Wallet(seed []string, config Config) (string, error) {
-	// Create a V4R2 wallet using the seed
-	w, err := wallet.FromSeed(nil, seed, wallet.V4R2)
-	if err != nil {
-		return "", err
+// handleResults checks every candidate address against job's pattern set, reports and records
+// each hit on the job, and returns true once the job's tracker says the whole search should
+// stop. report is called once per hit and returns the secret material to attach to the
+// recorded JobMatch: a mnemonic, a raw hex private key, or (in --pubkey-search mode, where the
+// worker never holds a private key) the winning child index.
+func handleResults(job *Job, results []matchResult, report func(versionName, addressStr string) string) bool {
+	for _, r := range results {
+		for _, m := range job.Config.Patterns.Match(r.Address) {
+			log.Printf("Matched pattern %q on %s address %s", m.Pattern.Name, r.VersionName, r.Address)
+			secret := report(r.VersionName, r.Address)
+
+			match := JobMatch{
+				Pattern: m.Pattern.Name,
+				Version: r.VersionName,
+				Address: r.Address,
+				FoundAt: time.Now(),
+			}
+			switch {
+			case job.Config.PubkeySearch != "":
+				match.ChildIndex = secret
+			case job.Config.Fast:
+				match.PrivateKey = secret
+			default:
+				match.Seed = secret
+			}
+
+			if job.recordMatch(match, m.Pattern) {
+				return true
+			}
+		}
 	}
+	return false
+}
+
+// matchResult is a candidate address for one wallet version, derived from the attempt's keypair.
+type matchResult struct {
+	VersionName string
+	Address     string
+}
+
+// addressesForPubKey computes the wallet address for every selected version straight from an
+// Ed25519 public key (StateInit hash), so a single keypair can be checked against N versions
+// without re-deriving it.
+func addressesForPubKey(pub ed25519.PublicKey, config Config) ([]matchResult, error) {
+	results := make([]matchResult, 0, len(config.Versions))
+	for _, spec := range config.Versions {
+		addr, err := wallet.AddressFromPubKey(pub, spec.Config, subwalletFor(spec, config.SubwalletID))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", spec.Name, err)
+		}
 
-	// Get the wallet address
-	addr := w.WalletAddress()
-	addressStr := addr.Testnet(config.Testnet).Bounce(config.Bounce).String()
-	return addressStr, nil
+		addressStr := addr.Testnet(config.Testnet).Bounce(config.Bounce).String()
+		results = append(results, matchResult{VersionName: spec.Name, Address: addressStr})
+	}
+	return results, nil
 }
 
-// logProgress logs how many wallets were processed in the last second
-func logProgress(counter *uint64, stopChan chan struct{}) {
+// logProgress logs how many wallets job processed in the last second
+func logProgress(job *Job) {
 	var lastCount uint64
 	for {
 		select {
-		case <-stopChan:
+		case <-job.stopChan:
 			return
 		case <-time.After(1 * time.Second):
-			currentCount := atomic.LoadUint64(counter)
+			currentCount := job.Attempts()
 			processedLastSecond := currentCount - lastCount
 			lastCount = currentCount
 			log.Printf("Processed %d addresses in the last second\n", processedLastSecond)
@@ -266,14 +448,62 @@ func getNetworkID(isTestnet bool) int32 {
 }
 
 // printFoundWallet prints the found seed and wallet address
-func printFoundWallet(seed []string, address string, output string) {
+func printFoundWallet(seed []string, versionName, address string, output string) {
 	fmt.Println("=== FOUND ===")
+	fmt.Println("Wallet version:", versionName)
 	fmt.Println("Seed phrase:", strings.Join(seed, " "))
 	fmt.Println("Wallet address:", address)
 
 	if output != "" {
 		timestamp := time.Now().Format("2006-01-02 15:04:05")
-		content := fmt.Sprintf("=== FOUND %s ===\nSeed: %s\nAddress: %s\n\n", timestamp, strings.Join(seed, " "), address)
+		content := fmt.Sprintf("=== FOUND %s ===\nVersion: %s\nSeed: %s\nAddress: %s\n\n", timestamp, versionName, strings.Join(seed, " "), address)
+		err := writeToFile(output, content)
+		if err != nil {
+			log.Printf("Failed to write to file: %v", err)
+		} else {
+			fmt.Printf("Results saved to: %s\n", output)
+		}
+	}
+}
+
+// printFoundWalletFast prints the winning raw Ed25519 private key found in --fast mode.
+// Unlike printFoundWallet, there is no BIP39 mnemonic: the fast path never derives one
+// per attempt, so the raw seed (hex) is what gets printed and saved instead.
+func printFoundWalletFast(priv ed25519.PrivateKey, versionName, address string, output string) {
+	seedHex := hex.EncodeToString(priv.Seed())
+
+	fmt.Println("=== FOUND ===")
+	fmt.Println("Wallet version:", versionName)
+	fmt.Println("Raw private key (hex):", seedHex)
+	fmt.Println("Wallet address:", address)
+	fmt.Println("Note: generated in --fast mode, no mnemonic was derived; import the raw key directly or re-derive a mnemonic for this key if your wallet supports it.")
+
+	if output != "" {
+		timestamp := time.Now().Format("2006-01-02 15:04:05")
+		content := fmt.Sprintf("=== FOUND %s ===\nVersion: %s\nRaw private key (hex): %s\nAddress: %s\n\n", timestamp, versionName, seedHex, address)
+		err := writeToFile(output, content)
+		if err != nil {
+			log.Printf("Failed to write to file: %v", err)
+		} else {
+			fmt.Printf("Results saved to: %s\n", output)
+		}
+	}
+}
+
+// printFoundWalletPubkey prints a winning child index found in --pubkey-search mode. The worker
+// never derived a private key, so unlike printFoundWallet/printFoundWalletFast there is no
+// secret to print here at all: the index is only enough to recover the wallet via
+// "tongen splitkey combine --index", run by whoever holds the matching ExtendedPrivateKey.
+func printFoundWalletPubkey(index uint32, versionName, address string, output string) {
+	fmt.Println("=== FOUND ===")
+	fmt.Println("Wallet version:", versionName)
+	fmt.Println("Child index:", index)
+	fmt.Println("Wallet address:", address)
+	fmt.Println("Note: generated in --pubkey-search mode, no private key was ever derived here; run 'tongen splitkey combine --index", index, "' wherever the matching extended private key lives to recover the wallet.")
+
+	if output != "" {
+		timestamp := time.Now().Format("2006-01-02 15:04:05")
+		content := fmt.Sprintf("=== FOUND %s ===\nVersion: %s\nChild index: %d\nAddress: %s\n\n", timestamp, versionName, index, address)
 		err := writeToFile(output, content)
 		if err != nil {
 			log.Printf("Failed to write to file: %v", err)
@@ -0,0 +1,360 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// PatternKind identifies how a Pattern's Value is matched against an address.
+type PatternKind string
+
+const (
+	KindPrefix   PatternKind = "prefix"
+	KindContains PatternKind = "contains"
+	KindSuffix   PatternKind = "suffix"
+	KindRegex    PatternKind = "regex"
+)
+
+// Pattern is a single vanity target: a literal (prefix/contains/suffix) or a regular
+// expression, matched against the address string with the 2-char workchain/bounce flag
+// prefix (e.g. "UQ"/"EQ") stripped off.
+type Pattern struct {
+	Name  string // label used in logs and per-pattern output filenames
+	Kind  PatternKind
+	Value string // literal text, or regex source for KindRegex
+}
+
+// PatternSet holds every configured target and evaluates them against a generated address in
+// O(len(address)) for the literal (prefix/contains/suffix) patterns via an Aho-Corasick
+// automaton, instead of O(N*len(address)) for a naive per-pattern scan. Regexes are evaluated
+// separately since they can't be folded into the automaton.
+type PatternSet struct {
+	literals      []Pattern
+	ac            *ahoCorasick
+	regexes       []Pattern
+	regexCompiled []*regexp.Regexp
+	caseSensitive bool
+}
+
+// PatternMatch is a Pattern that matched a given address.
+type PatternMatch struct {
+	Pattern Pattern
+}
+
+// NewPatternSet compiles prefixes/contains/suffixes/regexes (and, optionally, the contents of
+// patternsFile) into a ready-to-use PatternSet.
+func NewPatternSet(prefixes, contains, suffixes, regexes []string, patternsFile string, caseSensitive bool) (*PatternSet, error) {
+	ps := &PatternSet{caseSensitive: caseSensitive}
+
+	for _, v := range prefixes {
+		ps.addLiteral(KindPrefix, v)
+	}
+	for _, v := range contains {
+		ps.addLiteral(KindContains, v)
+	}
+	for _, v := range suffixes {
+		ps.addLiteral(KindSuffix, v)
+	}
+	for _, v := range regexes {
+		if err := ps.addRegex(v); err != nil {
+			return nil, err
+		}
+	}
+
+	if patternsFile != "" {
+		if err := ps.loadPatternsFile(patternsFile); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(ps.literals) == 0 && len(ps.regexes) == 0 {
+		return nil, fmt.Errorf("no patterns configured")
+	}
+
+	ps.ac = buildAhoCorasick(ps.literals, caseSensitive)
+	return ps, nil
+}
+
+func (ps *PatternSet) addLiteral(kind PatternKind, value string) {
+	if value == "" {
+		return
+	}
+	ps.literals = append(ps.literals, Pattern{
+		Name:  fmt.Sprintf("%s:%s", kind, value),
+		Kind:  kind,
+		Value: value,
+	})
+}
+
+func (ps *PatternSet) addRegex(src string) error {
+	if src == "" {
+		return nil
+	}
+	re, err := regexp.Compile(src)
+	if err != nil {
+		return fmt.Errorf("invalid --regex %q: %w", src, err)
+	}
+	ps.regexes = append(ps.regexes, Pattern{Name: fmt.Sprintf("regex:%s", src), Kind: KindRegex, Value: src})
+	ps.regexCompiled = append(ps.regexCompiled, re)
+	return nil
+}
+
+// loadPatternsFile reads one pattern per line in "kind:value" form (e.g. "prefix:ABC",
+// "contains:cats", "regex:^EQ.*X$"). A line with no recognized "kind:" prefix is treated as a
+// plain suffix, matching the tool's original --suffix behavior.
+func (ps *PatternSet) loadPatternsFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open patterns file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		kind, value, hasKind := strings.Cut(line, ":")
+		if !hasKind {
+			ps.addLiteral(KindSuffix, line)
+			continue
+		}
+
+		switch PatternKind(strings.ToLower(strings.TrimSpace(kind))) {
+		case KindPrefix:
+			ps.addLiteral(KindPrefix, value)
+		case KindContains:
+			ps.addLiteral(KindContains, value)
+		case KindSuffix:
+			ps.addLiteral(KindSuffix, value)
+		case KindRegex:
+			if err := ps.addRegex(value); err != nil {
+				return err
+			}
+		default:
+			ps.addLiteral(KindSuffix, line)
+		}
+	}
+	return scanner.Err()
+}
+
+// Match returns every pattern in the set that matches addressStr, stripped of its 2-character
+// workchain/bounce flag prefix (e.g. "UQ"/"EQ") as tongen's vanity targets are conventionally
+// expressed against the base64url body, not the flag bytes.
+func (ps *PatternSet) Match(addressStr string) []PatternMatch {
+	body := addressStr
+	if len(body) > 2 {
+		body = body[2:]
+	}
+	if !ps.caseSensitive {
+		body = strings.ToLower(body)
+	}
+
+	var matches []PatternMatch
+	if ps.ac != nil {
+		seen := make(map[int]bool, len(ps.literals))
+		for _, occ := range ps.ac.Search(body) {
+			if seen[occ.patternIdx] {
+				continue // a literal can occur more than once in body; count it at most once
+			}
+
+			lit := ps.literals[occ.patternIdx]
+			switch lit.Kind {
+			case KindPrefix:
+				if occ.start == 0 {
+					matches = append(matches, PatternMatch{lit})
+					seen[occ.patternIdx] = true
+				}
+			case KindSuffix:
+				if occ.end == len(body) {
+					matches = append(matches, PatternMatch{lit})
+					seen[occ.patternIdx] = true
+				}
+			case KindContains:
+				matches = append(matches, PatternMatch{lit})
+				seen[occ.patternIdx] = true
+			}
+		}
+	}
+
+	for i, re := range ps.regexCompiled {
+		if re.MatchString(body) {
+			matches = append(matches, PatternMatch{ps.regexes[i]})
+		}
+	}
+
+	return matches
+}
+
+// addressBodyLength is the length, in characters, of a friendly TON address once its
+// 2-character workchain/bounce flag (e.g. "UQ"/"EQ") is stripped off. Used only to turn a
+// --contains pattern's length into a rough keyspace estimate for job ETAs.
+const addressBodyLength = 48
+
+// base64urlAlphabetSize is the number of symbols a TON friendly address is drawn from.
+const base64urlAlphabetSize = 64
+
+// expectedAttempts returns a rough estimate of how many attempts it should take to satisfy
+// the easiest configured pattern: alphabetSize^patternLength for an anchored prefix/suffix, or
+// that divided by the number of possible start positions for a contains pattern. Regex-only
+// pattern sets have no cheap way to estimate a keyspace, so a conservative constant is used.
+func (ps *PatternSet) expectedAttempts() float64 {
+	best := math.Inf(1)
+
+	for _, lit := range ps.literals {
+		n := math.Pow(base64urlAlphabetSize, float64(len(lit.Value)))
+		if lit.Kind == KindContains {
+			positions := float64(addressBodyLength - len(lit.Value) + 1)
+			if positions < 1 {
+				positions = 1
+			}
+			n /= positions
+		}
+		if n < best {
+			best = n
+		}
+	}
+
+	if math.IsInf(best, 1) {
+		// Only regexes (or nothing) configured: fall back to a conservative guess.
+		return math.Pow(base64urlAlphabetSize, 4)
+	}
+	return best
+}
+
+// Names returns the names of every configured pattern, used to size/report total progress.
+func (ps *PatternSet) Names() []string {
+	names := make([]string, 0, len(ps.literals)+len(ps.regexes))
+	for _, p := range ps.literals {
+		names = append(names, p.Name)
+	}
+	for _, p := range ps.regexes {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// ahoCorasick is a minimal multi-pattern string matching automaton: it finds every occurrence
+// of every literal pattern in a single left-to-right scan of the text, in O(len(text) +
+// matches) instead of O(numPatterns*len(text)) for a naive per-pattern scan.
+type ahoCorasick struct {
+	children []map[byte]int
+	fail     []int
+	output   [][]int // pattern indices (into the literals slice) ending at this node
+	patLen   []int   // byte length of each literal pattern, indexed the same way
+}
+
+type acOccurrence struct {
+	patternIdx int
+	start, end int // [start, end) byte offsets into the searched text
+}
+
+func buildAhoCorasick(literals []Pattern, caseSensitive bool) *ahoCorasick {
+	ac := &ahoCorasick{
+		children: []map[byte]int{{}}, // node 0 is the root
+		fail:     []int{0},
+		output:   [][]int{nil},
+		patLen:   make([]int, len(literals)),
+	}
+	if len(literals) == 0 {
+		return ac
+	}
+
+	for idx, lit := range literals {
+		word := lit.Value
+		if !caseSensitive {
+			word = strings.ToLower(word)
+		}
+		ac.patLen[idx] = len(word)
+
+		node := 0
+		for i := 0; i < len(word); i++ {
+			c := word[i]
+			next, ok := ac.children[node][c]
+			if !ok {
+				ac.children = append(ac.children, map[byte]int{})
+				ac.fail = append(ac.fail, 0)
+				ac.output = append(ac.output, nil)
+				next = len(ac.children) - 1
+				ac.children[node][c] = next
+			}
+			node = next
+		}
+		ac.output[node] = append(ac.output[node], idx)
+	}
+
+	// Build fail links breadth-first; a node's full output set is its own matches plus
+	// whatever its fail link already matches, computed once here so Search never needs to
+	// walk the fail chain at runtime.
+	queue := make([]int, 0, len(ac.children))
+	for _, child := range ac.children[0] {
+		ac.fail[child] = 0
+		queue = append(queue, child)
+	}
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		for c, child := range ac.children[node] {
+			queue = append(queue, child)
+
+			f := ac.fail[node]
+			for {
+				if next, ok := ac.children[f][c]; ok {
+					ac.fail[child] = next
+					break
+				}
+				if f == 0 {
+					ac.fail[child] = 0
+					break
+				}
+				f = ac.fail[f]
+			}
+
+			ac.output[child] = append(ac.output[child], ac.output[ac.fail[child]]...)
+		}
+	}
+
+	return ac
+}
+
+// Search returns every occurrence of every literal pattern found in text.
+func (ac *ahoCorasick) Search(text string) []acOccurrence {
+	if len(ac.children) <= 1 {
+		return nil
+	}
+
+	var occurrences []acOccurrence
+	node := 0
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		for {
+			if next, ok := ac.children[node][c]; ok {
+				node = next
+				break
+			}
+			if node == 0 {
+				break
+			}
+			node = ac.fail[node]
+		}
+
+		for _, patIdx := range ac.output[node] {
+			end := i + 1
+			occurrences = append(occurrences, acOccurrence{
+				patternIdx: patIdx,
+				start:      end - ac.patLen[patIdx],
+				end:        end,
+			})
+		}
+	}
+
+	return occurrences
+}
@@ -0,0 +1,127 @@
+package main
+
+import "testing"
+
+func matchNames(t *testing.T, matches []PatternMatch) []string {
+	t.Helper()
+	names := make([]string, len(matches))
+	for i, m := range matches {
+		names[i] = m.Pattern.Name
+	}
+	return names
+}
+
+func TestPatternSetMatchLiteralKinds(t *testing.T) {
+	ps, err := NewPatternSet([]string{"AB"}, []string{"cd"}, []string{"ZZ"}, nil, "", false)
+	if err != nil {
+		t.Fatalf("NewPatternSet: %v", err)
+	}
+
+	// addressStr has its leading 2-char workchain/bounce flag stripped before matching, so the
+	// body checked below is "ABxxcdxxZZ".
+	got := matchNames(t, ps.Match("UQABxxcdxxZZ"))
+	want := []string{"prefix:AB", "contains:cd", "suffix:ZZ"}
+	if !sameSet(got, want) {
+		t.Errorf("Match() = %v, want %v", got, want)
+	}
+}
+
+func TestPatternSetMatchContainsOverlapDedupes(t *testing.T) {
+	// "aa" occurs twice in the body ("aaazzzz" -> "aa" at index 0 and 1), and "aaa" is also a
+	// configured prefix: a single address must still produce exactly one PatternMatch per
+	// configured pattern, not one per automaton occurrence.
+	ps, err := NewPatternSet([]string{"aaa"}, []string{"aa"}, nil, nil, "", false)
+	if err != nil {
+		t.Fatalf("NewPatternSet: %v", err)
+	}
+
+	got := matchNames(t, ps.Match("UQaaazzzz"))
+	want := []string{"prefix:aaa", "contains:aa"}
+	if !sameSet(got, want) {
+		t.Errorf("Match() = %v, want %v (no duplicate contains:aa entries)", got, want)
+	}
+}
+
+func TestPatternSetMatchPrefixRequiresAnchor(t *testing.T) {
+	ps, err := NewPatternSet([]string{"BC"}, nil, nil, nil, "", false)
+	if err != nil {
+		t.Fatalf("NewPatternSet: %v", err)
+	}
+
+	if got := ps.Match("UQABCD"); len(got) != 0 {
+		t.Errorf("Match() = %v, want no match ('BC' is not at the start of the body)", got)
+	}
+	if got := ps.Match("UQBCDE"); len(got) != 1 {
+		t.Errorf("Match() = %v, want exactly one match", got)
+	}
+}
+
+func TestPatternSetMatchSuffixRequiresAnchor(t *testing.T) {
+	ps, err := NewPatternSet(nil, nil, []string{"YZ"}, nil, "", false)
+	if err != nil {
+		t.Fatalf("NewPatternSet: %v", err)
+	}
+
+	if got := ps.Match("UQYZAB"); len(got) != 0 {
+		t.Errorf("Match() = %v, want no match ('YZ' is not at the end of the body)", got)
+	}
+	if got := ps.Match("UQABYZ"); len(got) != 1 {
+		t.Errorf("Match() = %v, want exactly one match", got)
+	}
+}
+
+func TestPatternSetMatchRegex(t *testing.T) {
+	// Match lowercases the body before testing it (unless --case-sensitive), but regex source
+	// is used as-is, so the pattern itself must already be lowercase to match.
+	ps, err := NewPatternSet(nil, nil, nil, []string{"^ab.*yz$"}, "", false)
+	if err != nil {
+		t.Fatalf("NewPatternSet: %v", err)
+	}
+
+	if got := ps.Match("UQABcdYZ"); len(got) != 1 {
+		t.Errorf("Match() = %v, want exactly one regex match", got)
+	}
+	if got := ps.Match("UQcdABYZ"); len(got) != 0 {
+		t.Errorf("Match() = %v, want no match (anchored regex doesn't match)", got)
+	}
+}
+
+func TestPatternSetMatchCaseSensitivity(t *testing.T) {
+	insensitive, err := NewPatternSet([]string{"ab"}, nil, nil, nil, "", false)
+	if err != nil {
+		t.Fatalf("NewPatternSet: %v", err)
+	}
+	if got := insensitive.Match("UQABcd"); len(got) != 1 {
+		t.Errorf("case-insensitive Match() = %v, want a match", got)
+	}
+
+	sensitive, err := NewPatternSet([]string{"ab"}, nil, nil, nil, "", true)
+	if err != nil {
+		t.Fatalf("NewPatternSet: %v", err)
+	}
+	if got := sensitive.Match("UQABcd"); len(got) != 0 {
+		t.Errorf("case-sensitive Match() = %v, want no match", got)
+	}
+	if got := sensitive.Match("UQabcd"); len(got) != 1 {
+		t.Errorf("case-sensitive Match() = %v, want a match", got)
+	}
+}
+
+func sameSet(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	count := make(map[string]int, len(want))
+	for _, w := range want {
+		count[w]++
+	}
+	for _, g := range got {
+		count[g]--
+	}
+	for _, c := range count {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// JobStatus is the lifecycle state of a Job.
+type JobStatus string
+
+const (
+	JobRunning  JobStatus = "running"
+	JobDone     JobStatus = "done"
+	JobCanceled JobStatus = "canceled"
+	JobFailed   JobStatus = "failed"
+)
+
+// JobMatch is a single pattern hit found by a job.
+type JobMatch struct {
+	Pattern    string    `json:"pattern"`
+	Version    string    `json:"version"`
+	Address    string    `json:"address"`
+	Seed       string    `json:"seed,omitempty"`
+	PrivateKey string    `json:"private_key_hex,omitempty"`
+	ChildIndex string    `json:"child_index,omitempty"` // set in --pubkey-search mode instead of Seed/PrivateKey
+	FoundAt    time.Time `json:"found_at"`
+}
+
+// Job is one vanity search owned by a Scheduler: its own worker pool, attempt counter,
+// pattern tracker and accumulated matches. This is what lets the daemon run many concurrent
+// searches side by side instead of the single global search the CLI originally supported.
+type Job struct {
+	ID        string    `json:"id"`
+	Config    Config    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	StartedAt time.Time `json:"started_at"`
+
+	attempts uint64
+	stopChan chan struct{}
+	once     sync.Once
+	tracker  *MatchTracker
+	wg       sync.WaitGroup
+
+	mu         sync.Mutex
+	status     JobStatus
+	finishedAt time.Time
+	matches    []JobMatch
+	err        error
+
+	rateMu     sync.Mutex
+	lastSample time.Time
+	lastCount  uint64
+	recentRate float64
+}
+
+// Attempts returns the number of addresses generated so far.
+func (j *Job) Attempts() uint64 { return atomic.LoadUint64(&j.attempts) }
+
+// Status returns the job's current lifecycle state.
+func (j *Job) Status() JobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.status
+}
+
+// Matches returns a copy of the matches found so far.
+func (j *Job) Matches() []JobMatch {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	out := make([]JobMatch, len(j.matches))
+	copy(out, j.matches)
+	return out
+}
+
+// recordMatch appends a match, bumps the tracker, and returns true once the job should stop.
+func (j *Job) recordMatch(m JobMatch, p Pattern) bool {
+	j.mu.Lock()
+	j.matches = append(j.matches, m)
+	j.mu.Unlock()
+
+	if j.tracker.Record(p, m.Version, m.Address, matchDetails(m)) {
+		j.once.Do(func() { close(j.stopChan) })
+		return true
+	}
+	return false
+}
+
+// matchDetails renders whichever secret field m carries (exactly one is set, depending on which
+// processWallets* variant found it) into the line appendPatternHit writes below the address, so
+// a pattern's output file is self-contained instead of requiring a cross-reference to job state.
+func matchDetails(m JobMatch) string {
+	switch {
+	case m.ChildIndex != "":
+		return "Child index: " + m.ChildIndex
+	case m.PrivateKey != "":
+		return "Raw private key (hex): " + m.PrivateKey
+	default:
+		return "Seed: " + m.Seed
+	}
+}
+
+// RecentRate returns an exponentially-smoothed attempts/sec estimate, refreshed at most once
+// per second so concurrent API callers don't thrash the sampling.
+func (j *Job) RecentRate() float64 {
+	j.rateMu.Lock()
+	defer j.rateMu.Unlock()
+
+	now := time.Now()
+	if j.lastSample.IsZero() {
+		j.lastSample = j.StartedAt
+	}
+
+	elapsed := now.Sub(j.lastSample).Seconds()
+	if elapsed < 1 {
+		return j.recentRate
+	}
+
+	current := j.Attempts()
+	rate := float64(current-j.lastCount) / elapsed
+	j.lastSample = now
+	j.lastCount = current
+	j.recentRate = rate
+	return rate
+}
+
+// ETA returns a rough estimate of time remaining, based on the keyspace implied by the
+// shortest configured literal pattern (the easiest one to satisfy) and the job's current
+// throughput. It's a heuristic, not a guarantee: contains/regex patterns and multiple
+// versions checked per attempt both shrink the real expected time below this estimate.
+func (j *Job) ETA() time.Duration {
+	rate := j.RecentRate()
+	if rate <= 0 {
+		return 0
+	}
+
+	remaining := float64(j.tracker.Remaining()) * j.Config.Patterns.expectedAttempts() / rate
+	if math.IsInf(remaining, 1) || math.IsNaN(remaining) {
+		return 0
+	}
+	return time.Duration(remaining * float64(time.Second))
+}
+
+// Cancel stops the job's workers without waiting for them to finish.
+func (j *Job) Cancel() {
+	j.once.Do(func() { close(j.stopChan) })
+	j.mu.Lock()
+	if j.status == JobRunning {
+		j.status = JobCanceled
+	}
+	j.mu.Unlock()
+}
+
+// Scheduler owns every running/finished Job, keyed by ID. It replaces the single global
+// worker pool runWalletGenerator used to start directly, so the daemon's HTTP API can run
+// many searches concurrently.
+type Scheduler struct {
+	mu     sync.Mutex
+	jobs   map[string]*Job
+	nextID uint64
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{jobs: make(map[string]*Job)}
+}
+
+// Submit starts a new job for config and returns immediately; the job runs in the background
+// until it's matched (and not --continue), canceled, or every pattern is satisfied.
+func (s *Scheduler) Submit(config Config) *Job {
+	if config.Threads == 0 {
+		config.Threads = runtime.NumCPU()
+	}
+
+	s.mu.Lock()
+	s.nextID++
+	id := fmt.Sprintf("job-%d", s.nextID)
+	s.mu.Unlock()
+
+	job := &Job{
+		ID:        id,
+		Config:    config,
+		CreatedAt: time.Now(),
+		StartedAt: time.Now(),
+		stopChan:  make(chan struct{}),
+		status:    JobRunning,
+		tracker:   NewMatchTracker(config.Patterns.Names(), config.OutputDir, config.Continue),
+	}
+
+	s.mu.Lock()
+	s.jobs[id] = job
+	s.mu.Unlock()
+
+	for i := 0; i < config.Threads; i++ {
+		job.wg.Add(1)
+		go func() {
+			defer job.wg.Done()
+			processWallets(job)
+		}()
+	}
+
+	go func() {
+		job.wg.Wait()
+		job.mu.Lock()
+		if job.status == JobRunning {
+			job.status = JobDone
+		}
+		job.finishedAt = time.Now()
+		job.mu.Unlock()
+	}()
+
+	return job
+}
+
+// Get returns the job with the given ID, if any.
+func (s *Scheduler) Get(id string) (*Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	return j, ok
+}
+
+// List returns every job known to the scheduler, in submission order.
+func (s *Scheduler) List() []*Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([]*Job, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		jobs = append(jobs, j)
+	}
+	return jobs
+}
+
+// Cancel stops the given job's workers and marks it canceled. It returns false if no such job.
+func (s *Scheduler) Cancel(id string) bool {
+	j, ok := s.Get(id)
+	if !ok {
+		return false
+	}
+	j.Cancel()
+	return true
+}
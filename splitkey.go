@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/ariadata/tongen/internal/hdkey"
+	"github.com/xssnick/tonutils-go/ton/wallet"
+	"github.com/xssnick/tonutils-go/tvm/cell"
+)
+
+// defaultSplitKeyFile is where "tongen splitkey init" writes the client's secret extended
+// private key, and where "tongen splitkey combine" reads it back from by default.
+const defaultSplitKeyFile = "tongen_splitkey.key"
+
+// runSplitKey dispatches the "tongen splitkey <init|combine>" subcommands. Unlike the search
+// flags handled by parseFlags, splitkey never touches the Scheduler: init and combine are both
+// one-shot local operations run by the client that owns the private key, offloading only the
+// address search itself (via --pubkey-search) to a worker that never sees it.
+func runSplitKey(args []string) {
+	if len(args) == 0 {
+		log.Fatal("Usage: tongen splitkey <init|combine> [flags]")
+	}
+
+	switch args[0] {
+	case "init":
+		runSplitKeyInit(args[1:])
+	case "combine":
+		runSplitKeyCombine(args[1:])
+	default:
+		log.Fatalf("Unknown splitkey subcommand %q (want \"init\" or \"combine\")", args[0])
+	}
+}
+
+// runSplitKeyInit generates a new master extended keypair, saves the private half locally, and
+// prints the public half for the client to hand to a worker alongside the desired pattern.
+func runSplitKeyInit(args []string) {
+	fs := flag.NewFlagSet("tongen splitkey init", flag.ExitOnError)
+	keyFile := fs.String("key-file", defaultSplitKeyFile, "Where to save the master extended private key (keep this secret; never send it anywhere)")
+	seedHex := fs.String("seed-hex", "", "32-byte hex seed to derive the master key from, instead of a random one (for reproducible testing only)")
+	fs.Parse(args)
+
+	var seed []byte
+	if *seedHex != "" {
+		var err error
+		seed, err = hex.DecodeString(*seedHex)
+		if err != nil {
+			log.Fatalf("Invalid --seed-hex: %v", err)
+		}
+	} else {
+		seed = make([]byte, 32)
+		if _, err := rand.Read(seed); err != nil {
+			log.Fatalf("Failed to generate seed: %v", err)
+		}
+	}
+
+	master, err := hdkey.NewMasterKey(seed)
+	if err != nil {
+		log.Fatalf("Failed to derive master key: %v", err)
+	}
+
+	if err := os.WriteFile(*keyFile, []byte(master.Marshal()), 0600); err != nil {
+		log.Fatalf("Failed to save key file: %v", err)
+	}
+
+	fmt.Println("=== SPLITKEY INIT ===")
+	fmt.Println("Private key file (keep secret):", *keyFile)
+	fmt.Println("Public key (send to worker with --pubkey-search):")
+	fmt.Println(master.Public().Marshal())
+}
+
+// runSplitKeyCombine derives the full wallet key for the winning child index a worker reported,
+// and prints its address for every selected version so the result can be checked against what
+// the worker found before the key is trusted.
+func runSplitKeyCombine(args []string) {
+	fs := flag.NewFlagSet("tongen splitkey combine", flag.ExitOnError)
+	keyFile := fs.String("key-file", defaultSplitKeyFile, "Master extended private key file saved by 'tongen splitkey init'")
+	index := fs.Uint("index", 0, "Winning child index reported by the worker")
+	versions := fs.String("versions", "v5r1", "Comma-separated wallet versions to print the address for (must match what the worker searched)")
+	subwalletID := fs.Int64("subwallet-id", -1, "Subwallet/wallet ID used for address derivation (must match what the worker searched)")
+	testnet := fs.Bool("testnet", false, "Use testnet")
+	bounce := fs.Bool("bounce", false, "Enable bounceable address")
+	fs.Parse(args)
+
+	raw, err := os.ReadFile(*keyFile)
+	if err != nil {
+		log.Fatalf("Failed to read key file: %v", err)
+	}
+
+	master, err := hdkey.ParsePrivateKey(string(raw))
+	if err != nil {
+		log.Fatalf("Failed to parse key file: %v", err)
+	}
+
+	child, err := master.Child(uint32(*index))
+	if err != nil {
+		log.Fatalf("Failed to derive child %d: %v", *index, err)
+	}
+
+	specs, err := parseVersions(*versions, *testnet, *subwalletID)
+	if err != nil {
+		log.Fatalf("Invalid --versions: %v", err)
+	}
+
+	childPub := child.Public().PublicKey()
+	results, err := addressesForPubKey(childPub, Config{
+		Versions:    specs,
+		SubwalletID: *subwalletID,
+		Testnet:     *testnet,
+		Bounce:      *bounce,
+	})
+	if err != nil {
+		log.Fatalf("Failed to derive address: %v", err)
+	}
+
+	fmt.Println("=== SPLITKEY COMBINE ===")
+	fmt.Println("Child index:", *index)
+	fmt.Println("Extended private key (hex, BIP32-Ed25519 scalar + chain code):", child.Marshal())
+	for i, r := range results {
+		w, err := wallet.FromSigner(nil, childPub, specs[i].Config, childSigner(child))
+		if err != nil {
+			log.Fatalf("Failed to build signer wallet for %s: %v", r.VersionName, err)
+		}
+		w, err = w.GetSubwallet(subwalletFor(specs[i], *subwalletID))
+		if err != nil {
+			log.Fatalf("Failed to select subwallet for %s: %v", r.VersionName, err)
+		}
+		signerAddr := w.WalletAddress().Testnet(*testnet).Bounce(*bounce).String()
+		if signerAddr != r.Address {
+			// childSigner and addressesForPubKey derive the address two different ways; if
+			// they disagree the signer would sign for the wrong contract, so refuse to print
+			// a usable-looking result rather than leave a silently unspendable one.
+			log.Fatalf("BUG: signer-constructed address %s for %s does not match derived address %s", signerAddr, r.VersionName, r.Address)
+		}
+		fmt.Printf("Wallet version: %s  Address: %s\n", r.VersionName, r.Address)
+	}
+	fmt.Println("Note: this is a raw BIP32-Ed25519 extended key, not a BIP39 seed -- crypto/ed25519.Sign would hash and clamp it into a different scalar than the one the address above was derived from. To actually spend from this address, build a wallet with wallet.FromSigner (not wallet.FromPrivateKey) using a Signer backed by ExtendedPrivateKey.Sign, as childSigner below does.")
+}
+
+// childSigner adapts an hdkey.ExtendedPrivateKey into a tonutils-go wallet.Signer, so a wallet
+// recovered by splitkey combine can actually send transactions despite its key being a raw,
+// unclamped scalar that crypto/ed25519 (and so wallet.FromPrivateKey) can't sign with correctly.
+func childSigner(child *hdkey.ExtendedPrivateKey) wallet.Signer {
+	return func(_ context.Context, toSign *cell.Cell, _ uint32) ([]byte, error) {
+		return child.Sign(toSign.Hash())
+	}
+}
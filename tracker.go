@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+)
+
+var unsafeFilenameChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// MatchTracker records which patterns have been satisfied at least once and, when
+// outputDir is set, appends every hit to a per-pattern file under it. With continueSearch
+// off it behaves like the original single-shot tool: the first hit is enough to stop. With
+// it on, the search keeps running until every configured pattern has been satisfied.
+type MatchTracker struct {
+	mu         sync.Mutex
+	satisfied  map[string]bool
+	total      int
+	outputDir  string
+	continueOn bool
+}
+
+func NewMatchTracker(patternNames []string, outputDir string, continueOn bool) *MatchTracker {
+	return &MatchTracker{
+		satisfied:  make(map[string]bool, len(patternNames)),
+		total:      len(patternNames),
+		outputDir:  outputDir,
+		continueOn: continueOn,
+	}
+}
+
+// Record registers a hit for the given pattern and returns true once the search should stop:
+// immediately if --continue wasn't given, or once every pattern has been hit at least once.
+func (t *MatchTracker) Record(p Pattern, versionName, addressStr, details string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.outputDir != "" {
+		if err := appendPatternHit(t.outputDir, p, versionName, addressStr, details); err != nil {
+			log.Printf("Failed to write pattern output file: %v", err)
+		}
+	}
+
+	t.satisfied[p.Name] = true
+
+	if !t.continueOn {
+		return true
+	}
+	return len(t.satisfied) >= t.total
+}
+
+// Remaining returns how many configured patterns have not been satisfied yet.
+func (t *MatchTracker) Remaining() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	remaining := t.total - len(t.satisfied)
+	if remaining < 1 {
+		remaining = 1 // a finished/near-finished job still reports a small positive ETA input
+	}
+	return remaining
+}
+
+// appendPatternHit writes outputDir and the per-pattern file it appends to with owner-only
+// permissions: details now carries the winning seed/private key/child index (see matchDetails),
+// the same secret splitkey.go's key file is careful to save as 0600.
+func appendPatternHit(outputDir string, p Pattern, versionName, addressStr, details string) error {
+	if err := os.MkdirAll(outputDir, 0700); err != nil {
+		return fmt.Errorf("failed to create output dir: %w", err)
+	}
+
+	name := unsafeFilenameChars.ReplaceAllString(p.Name, "_") + ".txt"
+	path := filepath.Join(outputDir, name)
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	timestamp := time.Now().Format("2006-01-02 15:04:05")
+	_, err = fmt.Fprintf(f, "=== FOUND %s ===\nPattern: %s\nVersion: %s\nAddress: %s\n%s\n\n",
+		timestamp, p.Name, versionName, addressStr, details)
+	return err
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xssnick/tonutils-go/ton/wallet"
+)
+
+// defaultHighloadTTL is the message TTL baked into the highload V3 StateInit when no other
+// value is configurable from the CLI. It only affects the address (it's hashed into the
+// contract data), not actual message validity, since tongen never sends transactions.
+const defaultHighloadTTL = 1 << 16
+
+// VersionSpec pairs a human-readable wallet version name with the VersionConfig tonutils-go
+// needs to derive its address, so a single attempt can be checked against every selected
+// version without re-deriving the keypair.
+type VersionSpec struct {
+	Name   string
+	Config wallet.VersionConfig
+}
+
+// parseVersions turns a comma-separated --versions value (e.g. "v4r2,v5r1,highloadv2r2") into
+// the VersionSpecs processWallets checks on every attempt. subwalletID, when negative, means
+// "use this version's own default subwallet", matching tonutils-go's newWallet behavior.
+func parseVersions(raw string, testnet bool, subwalletID int64) ([]VersionSpec, error) {
+	networkID := getNetworkID(testnet)
+
+	var specs []VersionSpec
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		switch name {
+		case "v3r1":
+			specs = append(specs, VersionSpec{Name: "V3R1", Config: wallet.V3R1})
+		case "v3r2", "v3":
+			specs = append(specs, VersionSpec{Name: "V3R2", Config: wallet.V3R2})
+		case "v4r1":
+			specs = append(specs, VersionSpec{Name: "V4R1", Config: wallet.V4R1})
+		case "v4r2", "v4":
+			specs = append(specs, VersionSpec{Name: "V4R2", Config: wallet.V4R2})
+		case "v5r1beta", "v5beta":
+			specs = append(specs, VersionSpec{Name: "V5R1Beta", Config: wallet.ConfigV5R1Beta{
+				NetworkGlobalID: networkID,
+				Workchain:       0,
+			}})
+		case "v5r1", "v5r1final", "v5", "v5final":
+			specs = append(specs, VersionSpec{Name: "V5R1Final", Config: wallet.ConfigV5R1Final{
+				NetworkGlobalID: networkID,
+				Workchain:       0,
+			}})
+		case "highloadv2r2", "highload2":
+			specs = append(specs, VersionSpec{Name: "HighloadV2R2", Config: wallet.HighloadV2R2})
+		case "highloadv3", "highload3", "highload":
+			specs = append(specs, VersionSpec{Name: "HighloadV3", Config: wallet.ConfigHighloadV3{
+				MessageTTL: defaultHighloadTTL,
+			}})
+		default:
+			return nil, fmt.Errorf("unknown wallet version %q", name)
+		}
+	}
+
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no wallet versions selected")
+	}
+
+	return specs, nil
+}
+
+// subwalletFor resolves the subwallet/wallet ID to use for a given version, honoring an
+// explicit --subwallet-id override or falling back to the same per-version defaults
+// tonutils-go's own newWallet uses. Only ConfigV5R1Final defaults to 0 there -- despite the
+// similar name, ConfigV5R1Beta falls through to DefaultSubwallet like every other version.
+func subwalletFor(spec VersionSpec, explicit int64) uint32 {
+	if explicit >= 0 {
+		return uint32(explicit)
+	}
+
+	switch spec.Config.(type) {
+	case wallet.ConfigV5R1Final:
+		return 0
+	default:
+		return wallet.DefaultSubwallet
+	}
+}